@@ -0,0 +1,128 @@
+// This file scrapes the list of known clang-tidy checks and provides a
+// small API to canonicalize a `-checks=` glob expression (e.g.
+// "google*,-modernize-*") against that list, so callers can treat
+// differently-ordered but equivalent `-checks=` strings as the same cache
+// key.
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const ClangTidyChecksListUrl = "https://clang.llvm.org/extra/clang-tidy/checks/list.html"
+
+// clangTidyCheckRE matches a clang-tidy check name as rendered by the
+// Sphinx `:doc:` cross-reference used in the checks list page, e.g.
+//
+//	<span class="std std-doc">bugprone-argument-comment</span>
+var clangTidyCheckRE = regexp.MustCompile(`<span class="std std-doc">([\w][\w-]*)</span>`)
+
+// parseClangTidyChecksDocument loads the clang-tidy checks list page and
+// parses it to list all known check names. The result might contain
+// duplicates.
+func parseClangTidyChecksDocument() ([]string, error) {
+	body, err := fetchDocument("clang-tidy-checks-list.html", ClangTidyChecksListUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	var checks []string
+	for _, matched := range clangTidyCheckRE.FindAllStringSubmatch(body, -1) {
+		checks = append(checks, matched[1])
+	}
+
+	return checks, nil
+}
+
+// expandChecksGlob expands a single `-checks=` term's pattern (with any
+// leading "-" already stripped by the caller), e.g. "google*", against
+// known, the full list of known checks, returning every matching check
+// name. A non-glob pattern is returned as-is, even if it's not present in
+// known, so that `-checks=` can still name a check this generator hasn't
+// seen.
+func expandChecksGlob(pattern string, known []string) []string {
+	if !strings.Contains(pattern, "*") {
+		return []string{pattern}
+	}
+
+	patternRE := regexp.MustCompile("^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$")
+	var matches []string
+	for _, check := range known {
+		if patternRE.MatchString(check) {
+			matches = append(matches, check)
+		}
+	}
+	return matches
+}
+
+// normalizeChecks canonicalizes a `-checks=` argument the way clang-tidy
+// itself resolves it: terms are applied left to right, each expanding its
+// glob (if any) against known and setting the enabled/disabled state of
+// every check it matches, with later terms overriding earlier ones for the
+// same check. This means "*,google*,-modernize-*,-readability-*" ends up
+// enabling every check except those under "modernize-" and "readability-",
+// not producing contradictory entries for the same check. The result is
+// the sorted list of checks left enabled or explicitly disabled (with
+// their leading "-" preserved), deduplicated by check name.
+func normalizeChecks(checksArg string, known []string) string {
+	enabled := make(map[string]bool)
+	for _, term := range strings.Split(checksArg, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		disable := strings.HasPrefix(term, "-")
+		pattern := strings.TrimPrefix(term, "-")
+		for _, check := range expandChecksGlob(pattern, known) {
+			enabled[check] = !disable
+		}
+	}
+
+	var result []string
+	for check, isEnabled := range enabled {
+		if isEnabled {
+			result = append(result, check)
+		} else {
+			result = append(result, "-"+check)
+		}
+	}
+	sort.Strings(result)
+	return strings.Join(result, ",")
+}
+
+// writeKnownClangTidyChecksHeader writes the flat, deduplicated list of
+// known clang-tidy check names to path.
+func writeKnownClangTidyChecksHeader(path string, sortedChecks []string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fmt.Fprint(f, `// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// This is auto generated by build/generate_known_warnings_list.go
+// DO NOT EDIT
+`)
+	writeSourcesComment(f)
+	fmt.Fprint(f, `
+#ifndef DEVTOOLS_GOMA_LIB_KNOWN_CLANG_TIDY_CHECKS_H_
+#define DEVTOOLS_GOMA_LIB_KNOWN_CLANG_TIDY_CHECKS_H_
+
+namespace devtools_goma {
+const char* const kKnownClangTidyChecks[] {
+`)
+	for _, c := range sortedChecks {
+		fmt.Fprintf(f, "  \"%s\",\n", c)
+	}
+	fmt.Fprint(f, `};
+}  // namespace devtools_goma
+
+#endif  // DEVTOOLS_GOMA_LIB_KNOWN_CLANG_TIDY_CHECKS_H_
+`)
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestNormalizeChecks(t *testing.T) {
+	known := []string{
+		"bugprone-argument-comment",
+		"google-runtime-int",
+		"modernize-use-auto",
+		"readability-braces-around-statements",
+	}
+
+	tests := []struct {
+		name string
+		arg  string
+		want string
+	}{
+		{
+			name: "disable all then re-enable a glob, matching the request's own example",
+			arg:  "*,google*,-modernize-*,-readability-*",
+			want: "-modernize-use-auto,-readability-braces-around-statements,bugprone-argument-comment,google-runtime-int",
+		},
+		{
+			name: "later term wins for the same check",
+			arg:  "-modernize-use-auto,modernize-use-auto",
+			want: "modernize-use-auto",
+		},
+		{
+			name: "a later '*' re-enables a check an earlier term disabled",
+			arg:  "-readability-*,google*,*,-modernize-*",
+			want: "-modernize-use-auto,bugprone-argument-comment,google-runtime-int,readability-braces-around-statements",
+		},
+		{
+			name: "unknown literal check name is passed through",
+			arg:  "-some-future-check",
+			want: "-some-future-check",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeChecks(tt.arg, known); got != tt.want {
+				t.Errorf("normalizeChecks(%q) = %q, want %q", tt.arg, got, tt.want)
+			}
+		})
+	}
+}
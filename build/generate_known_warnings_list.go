@@ -1,24 +1,97 @@
-/* Generate known_warnings_options.cc from gcc documents.
+/* Generate known_warnings_options.h and known_warning_groups.h from gcc and
+clang documents.
 
 How to run:
-  $ go run generate_known_warnings_list.go > lib/known_warnings_options.h
+  $ go run generate_known_warnings_list.go -output-dir=lib
+
+For a reproducible, offline run against a specific upstream snapshot:
+  $ go run generate_known_warnings_list.go -output-dir=lib \
+      -clang-ref=llvmorg-18.1.0 -gcc-version=13.2.0 \
+      -offline -input-dir=/path/to/downloaded/docs
 */
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
 )
 
+var (
+	outputDir  = flag.String("output-dir", ".", "directory to write the generated headers into")
+	clangRef   = flag.String("clang-ref", "main", "git ref (branch, tag, or commit) of llvm-project to fetch DiagnosticGroups.td from")
+	gccVersion = flag.String("gcc-version", "", "GCC version (e.g. 13.2.0) whose Warning-Options.html to fetch; defaults to the latest docs")
+	offline    = flag.Bool("offline", false, "read pre-downloaded documents from -input-dir instead of fetching them over HTTP")
+	inputDir   = flag.String("input-dir", "", "directory containing pre-downloaded documents, used when -offline is set")
+
+	normalizeChecksExpr = flag.String("normalize-checks", "", "if set, print the canonicalized form of this clang-tidy -checks= expression (expanded against the freshly loaded checks list) instead of generating headers")
+
+	format = flag.String("format", "cpp", `output format for the known warning options and groups: "cpp" (default, writes known_warnings_options.h and known_warning_groups.h), "go" (writes known_warnings.go), or "json" (writes known_warnings.json)`)
+)
+
 const GnuDocumentUrl = "https://gcc.gnu.org/onlinedocs/gcc/Warning-Options.html"
 const ClangDocumentUrl = "https://clang.llvm.org/docs/DiagnosticsReference.html"
 const ClangRepositoryUrl = "https://raw.githubusercontent.com/llvm/llvm-project/main/clang/include/clang/Basic/DiagnosticGroups.td"
 
+// gnuDocumentURL returns the (optionally version-pinned) GCC warning
+// options page.
+func gnuDocumentURL() string {
+	if *gccVersion == "" {
+		return GnuDocumentUrl
+	}
+	return fmt.Sprintf("https://gcc.gnu.org/onlinedocs/gcc-%s/gcc/Warning-Options.html", *gccVersion)
+}
+
+// clangRepositoryURL returns the DiagnosticGroups.td URL pinned to
+// -clang-ref (a branch, tag, or commit of llvm-project).
+func clangRepositoryURL() string {
+	return fmt.Sprintf("https://raw.githubusercontent.com/llvm/llvm-project/%s/clang/include/clang/Basic/DiagnosticGroups.td", *clangRef)
+}
+
+// sourceSnapshot records where a piece of generated data came from and a
+// hash of its contents, so that the generated headers can be audited
+// against the upstream snapshot that produced them.
+type sourceSnapshot struct {
+	url    string
+	sha256 string
+}
+
+// sources accumulates one sourceSnapshot per document fetched or read by
+// fetchDocument, in the order they were loaded.
+var sources []sourceSnapshot
+
+// fetchDocument returns the contents of url, either fetched over HTTP or,
+// when -offline is set, read from localName inside -input-dir. Every call
+// records a sourceSnapshot for the returned content.
+func fetchDocument(localName, url string) (string, error) {
+	var body string
+	if *offline {
+		data, err := os.ReadFile(filepath.Join(*inputDir, localName))
+		if err != nil {
+			return "", err
+		}
+		body = string(data)
+	} else {
+		var err error
+		body, err = loadFromWeb(url)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	sum := sha256.Sum256([]byte(body))
+	sources = append(sources, sourceSnapshot{url: url, sha256: hex.EncodeToString(sum[:])})
+	return body, nil
+}
+
 // Already known warnings. These warnings will be merged.
 var knownWarnings = []string{
 	"",
@@ -525,7 +598,7 @@ func loadFromWeb(url string) (string, error) {
 // parseGnuDocument loads gnu document and parses it to list all warnings.
 // The result might contain "no-" form warnings or duplicate.
 func parseGnuDocument() ([]string, error) {
-	body, err := loadFromWeb(GnuDocumentUrl)
+	body, err := fetchDocument("gcc-warning-options.html", gnuDocumentURL())
 	if err != nil {
 		return nil, err
 	}
@@ -547,7 +620,7 @@ func parseGnuDocument() ([]string, error) {
 // parseClangDocument loads clang document and parses it to list all warnings.
 // The result might contain "no-" form warnings or duplicate.
 func parseClangDocument() ([]string, error) {
-	body, err := loadFromWeb(ClangDocumentUrl)
+	body, err := fetchDocument("clang-diagnostics-reference.html", ClangDocumentUrl)
 	if err != nil {
 		return nil, err
 	}
@@ -567,35 +640,265 @@ func parseClangDocument() ([]string, error) {
 	return warnings, nil
 }
 
-// parseClangRepository loads flag config file from llvm repository.
-func parseClangRepository() ([]string, error) {
-	body, err := loadFromWeb(ClangRepositoryUrl)
+// collapseAngleBrackets replaces newlines and tabs found inside "<...>"
+// spans with a single space, so that a `DiagGroup<"name", [Child1,\n
+// Child2]>` record that is wrapped across multiple lines can be matched by
+// a single-line regexp.
+func collapseAngleBrackets(s string) string {
+	var out strings.Builder
+	depth := 0
+	for _, r := range s {
+		switch r {
+		case '<':
+			depth++
+			out.WriteRune(r)
+		case '>':
+			if depth > 0 {
+				depth--
+			}
+			out.WriteRune(r)
+		case '\n', '\t':
+			if depth > 0 {
+				out.WriteRune(' ')
+			} else {
+				out.WriteRune(r)
+			}
+		default:
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
+
+// diagGroupRE matches a `def Name : DiagGroup<"warning-name"[, [Child1,
+// Child2]]>` record. `Name` is the tablegen def name (empty for anonymous
+// defs such as `def : DiagGroup<"switch-default">;`), used to resolve the
+// child identifiers listed in a parent's DiagGroup<> to their string names.
+//
+// This intentionally does not handle tablegen's `in Group<Parent> { ... }`
+// block sugar: that construct isn't used in the upstream
+// DiagnosticGroups.td to add children to a DiagGroup, so every group's
+// children are declared directly in its own DiagGroup<> record, which this
+// regexp captures in full.
+var diagGroupRE = regexp.MustCompile(`def\s*(\w*)\s*:\s*DiagGroup<"([^"]*)"(?:\s*,\s*\[\s*([^\]]*)\s*\])?>`)
+
+// parseClangRepository loads the flag config file from the llvm repository
+// and parses it into the flat list of warning names plus the parent/child
+// warning group graph (e.g. "-Wall" includes "-Wmost" includes
+// "-Wextra-tokens").
+//
+// clang warning flags config has flag config like below.
+//
+//	def ObjCStringComparison : DiagGroup<"objc-string-compare">;
+//	def : DiagGroup<"switch-default">;
+//	def Shadow : DiagGroup<"shadow", [ShadowFieldInConstructorModified,
+//	                                   ShadowField, ShadowUncapturedLocal]>;
+func parseClangRepository() ([]string, map[string][]string, error) {
+	body, err := fetchDocument("clang-diagnostic-groups.td", clangRepositoryURL())
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	body = collapseAngleBrackets(body)
+
 	var warnings []string
-	// clang warning flags config has flag config like belows.
-	// * def ObjCStringComparison : DiagGroup<"objc-string-compare">;
-	// * def : DiagGroup<"switch-default">;
-	// * def Shadow : DiagGroup<"shadow", [ShadowFieldInConstructorModified,
-	codeRE := regexp.MustCompile(`def.* : DiagGroup<"(.*)"`)
-	for _, matched := range codeRE.FindAllStringSubmatch(body, -1) {
-		s := matched[1]
-		s = removeAfterEqual(s)
-		warnings = append(warnings, strings.TrimSpace(s))
+	defNameToWarning := make(map[string]string)
+	type diagGroupDef struct {
+		name     string
+		children []string // def names of children, not yet resolved to warning names
 	}
+	var defs []diagGroupDef
 
-	return warnings, nil
+	for _, matched := range diagGroupRE.FindAllStringSubmatch(body, -1) {
+		defName := matched[1]
+		name := removeAfterEqual(strings.TrimSpace(matched[2]))
+		warnings = append(warnings, name)
+		if defName != "" {
+			defNameToWarning[defName] = name
+		}
+
+		var children []string
+		for _, c := range strings.Split(matched[3], ",") {
+			c = strings.TrimSpace(c)
+			if c != "" {
+				children = append(children, c)
+			}
+		}
+		defs = append(defs, diagGroupDef{name: name, children: children})
+	}
+
+	groups := make(map[string][]string)
+	for _, d := range defs {
+		for _, c := range d.children {
+			if childName, ok := defNameToWarning[c]; ok {
+				groups[d.name] = append(groups[d.name], childName)
+			}
+		}
+	}
+
+	for name := range groups {
+		sort.Strings(groups[name])
+	}
+
+	return warnings, groups, nil
+}
+
+// sanitizeIdentifier turns a warning name such as "c++-compat" into
+// something that's safe to splice into a C++ identifier, e.g.
+// "c___compat".
+func sanitizeIdentifier(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// writeSourcesComment writes a comment block listing every upstream
+// document that was fetched or read this run, along with a sha256 of its
+// contents, so a generated header can be audited against the snapshot that
+// produced it.
+func writeSourcesComment(f *os.File) {
+	fmt.Fprint(f, "//\n// Generated from the following upstream sources:\n")
+	for _, s := range sources {
+		fmt.Fprintf(f, "//   %s\n//     sha256: %s\n", s.url, s.sha256)
+	}
+}
+
+// writeKnownWarningOptionsHeader writes the flat, deduplicated list of known
+// "-W..." names to path.
+func writeKnownWarningOptionsHeader(path string, sortedWarnings []string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fmt.Fprint(f, `// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// This is auto generated by build/generate_known_warnings_list.go
+// DO NOT EDIT
+`)
+	writeSourcesComment(f)
+	fmt.Fprint(f, `
+#ifndef DEVTOOLS_GOMA_LIB_KNOWN_WARNING_OPTIONS_H_
+#define DEVTOOLS_GOMA_LIB_KNOWN_WARNING_OPTIONS_H_
+
+namespace devtools_goma {
+const char* const kKnownWarningOptions[] {
+`)
+	for _, w := range sortedWarnings {
+		fmt.Fprintf(f, "  \"%s\",\n", w)
+	}
+	fmt.Fprint(f, `};
+}  // namespace devtools_goma
+
+#endif  // DEVTOOLS_GOMA_LIB_KNOWN_WARNING_OPTIONS_H_
+`)
+	return nil
+}
+
+// writeKnownWarningGroupsHeader writes the warning group graph (e.g.
+// "-Wall" includes "-Wmost") to path, so that callers can resolve what a
+// "-Wno-foo" actually silences.
+func writeKnownWarningGroupsHeader(path string, groups map[string][]string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var sortedGroups []string
+	for name := range groups {
+		sortedGroups = append(sortedGroups, name)
+	}
+	sort.Strings(sortedGroups)
+
+	seenIdentifiers := make(map[string]string)
+	for _, name := range sortedGroups {
+		id := sanitizeIdentifier(name)
+		if other, ok := seenIdentifiers[id]; ok {
+			return fmt.Errorf("warning group names %q and %q both sanitize to identifier %q", other, name, id)
+		}
+		seenIdentifiers[id] = name
+	}
+
+	fmt.Fprint(f, `// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// This is auto generated by build/generate_known_warnings_list.go
+// DO NOT EDIT
+`)
+	writeSourcesComment(f)
+	fmt.Fprint(f, `
+#ifndef DEVTOOLS_GOMA_LIB_KNOWN_WARNING_GROUPS_H_
+#define DEVTOOLS_GOMA_LIB_KNOWN_WARNING_GROUPS_H_
+
+#include <cstddef>
+
+namespace devtools_goma {
+
+`)
+	for _, name := range sortedGroups {
+		fmt.Fprintf(f, "static const char* const kWarningGroupChildren_%s[] = {\n", sanitizeIdentifier(name))
+		for _, child := range groups[name] {
+			fmt.Fprintf(f, "  \"%s\",\n", child)
+		}
+		fmt.Fprint(f, "};\n\n")
+	}
+
+	fmt.Fprint(f, `struct KnownWarningGroup {
+  const char* name;
+  const char* const* children;
+  size_t num_children;
+};
+
+const KnownWarningGroup kKnownWarningGroups[] {
+`)
+	for _, name := range sortedGroups {
+		fmt.Fprintf(f, "  {\"%s\", kWarningGroupChildren_%s, %d},\n", name, sanitizeIdentifier(name), len(groups[name]))
+	}
+	fmt.Fprint(f, `};
+
+}  // namespace devtools_goma
+
+#endif  // DEVTOOLS_GOMA_LIB_KNOWN_WARNING_GROUPS_H_
+`)
+	return nil
 }
 
 func main() {
+	flag.Parse()
+
+	clangTidyChecks, err := parseClangTidyChecksDocument()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read clang-tidy checks list: %v\n", err)
+		os.Exit(1)
+	}
+
+	checks := make(map[string]bool)
+	for _, c := range clangTidyChecks {
+		checks[c] = true
+	}
+	var sortedChecks []string
+	for c := range checks {
+		sortedChecks = append(sortedChecks, c)
+	}
+	sort.Strings(sortedChecks)
+
+	if *normalizeChecksExpr != "" {
+		fmt.Println(normalizeChecks(*normalizeChecksExpr, sortedChecks))
+		return
+	}
+
 	clangWarnings, err := parseClangDocument()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to read clang documents: %v\n", err)
 		os.Exit(1)
 	}
 
-	clangWarningsInRepository, err := parseClangRepository()
+	clangWarningsInRepository, groups, err := parseClangRepository()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to read clang reposotiry: %v\n", err)
 		os.Exit(1)
@@ -627,23 +930,39 @@ func main() {
 	}
 	sort.Strings(sortedWarnings)
 
-	fmt.Print(`// Copyright 2017 Google Inc. All Rights Reserved.
-//
-// This is auto generated by build/generate_known_warnings_list.go
-// DO NOT EDIT
-
-#ifndef DEVTOOLS_GOMA_LIB_KNOWN_WARNING_OPTIONS_H_
-#define DEVTOOLS_GOMA_LIB_KNOWN_WARNING_OPTIONS_H_
-
-namespace devtools_goma {
-const char* const kKnownWarningOptions[] {
-`)
-	for _, w := range sortedWarnings {
-		fmt.Printf("  \"%s\",\n", w)
+	switch *format {
+	case "cpp":
+		if err := writeKnownWarningOptionsHeader(filepath.Join(*outputDir, "known_warnings_options.h"), sortedWarnings); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write known_warnings_options.h: %v\n", err)
+			os.Exit(1)
+		}
+		if err := writeKnownWarningGroupsHeader(filepath.Join(*outputDir, "known_warning_groups.h"), groups); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write known_warning_groups.h: %v\n", err)
+			os.Exit(1)
+		}
+		if err := writeKnownMsvcWarningOptionsHeader(filepath.Join(*outputDir, "known_msvc_warning_options.h"), knownMsvcWarningFlags()); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write known_msvc_warning_options.h: %v\n", err)
+			os.Exit(1)
+		}
+	case "go":
+		if err := writeGoOutput(filepath.Join(*outputDir, "known_warnings.go"), sortedWarnings, groups); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write known_warnings.go: %v\n", err)
+			os.Exit(1)
+		}
+	case "json":
+		if err := writeJsonOutput(filepath.Join(*outputDir, "known_warnings.json"), sortedWarnings, groups); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write known_warnings.json: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown -format %q: must be one of cpp, go, json\n", *format)
+		os.Exit(1)
 	}
-	fmt.Print(`};
-}  // namespace devtools_goma
 
-#endif  // DEVTOOLS_GOMA_LIB_KNOWN_WARNING_OPTIONS_H_
-`)
+	if *format == "cpp" {
+		if err := writeKnownClangTidyChecksHeader(filepath.Join(*outputDir, "known_clang_tidy_checks.h"), sortedChecks); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write known_clang_tidy_checks.h: %v\n", err)
+			os.Exit(1)
+		}
+	}
 }
@@ -0,0 +1,110 @@
+// This file enumerates the known MSVC/clang-cl warning flags, which use a
+// different syntax than GCC/Clang's GNU-style "-W...".
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// knownMsvcWarningIds is a curated list of MSVC/clang-cl numeric warning
+// IDs, taken from the warnings most commonly toggled in Chromium and
+// Chrome OS build configs. Unlike kKnownWarningOptions, there's no single
+// upstream page to scrape: the full list lives in clang-cl's diagnostic ID
+// table (clang/include/clang/Basic/Diagnostic*Kinds.td) keyed by MSVC
+// compatibility ID, so we keep a small hand-maintained set here rather than
+// trying to mirror it exactly.
+var knownMsvcWarningIds = []string{
+	"4005",
+	"4018",
+	"4101",
+	"4146",
+	"4244",
+	"4251",
+	"4267",
+	"4275",
+	"4291",
+	"4305",
+	"4309",
+	"4351",
+	"4355",
+	"4456",
+	"4457",
+	"4458",
+	"4459",
+	"4503",
+	"4512",
+	"4702",
+	"4800",
+	"4819",
+	"4996",
+}
+
+// msvcWarningLevelFlags returns the clang-cl/MSVC global warning-level
+// toggles: /W0 through /W4, and /Wall.
+func msvcWarningLevelFlags() []string {
+	var flags []string
+	for level := 0; level <= 4; level++ {
+		flags = append(flags, fmt.Sprintf("/W%d", level))
+	}
+	flags = append(flags, "/Wall")
+	return flags
+}
+
+// msvcWarningIdFlags returns, for each numeric warning ID, the per-ID
+// toggles clang-cl and cl.exe accept:
+//   /wdNNNN  disable warning NNNN
+//   /weNNNN  treat warning NNNN as an error
+//   /woNNNN  report warning NNNN once
+//   /wLNNNN  set the warning level of NNNN to L (0-4)
+func msvcWarningIdFlags(ids []string) []string {
+	var flags []string
+	for _, id := range ids {
+		flags = append(flags, "/wd"+id, "/we"+id, "/wo"+id)
+		for level := 0; level <= 4; level++ {
+			flags = append(flags, fmt.Sprintf("/w%d%s", level, id))
+		}
+	}
+	return flags
+}
+
+// knownMsvcWarningFlags returns the full, sorted set of known MSVC/clang-cl
+// warning flags.
+func knownMsvcWarningFlags() []string {
+	flags := msvcWarningLevelFlags()
+	flags = append(flags, msvcWarningIdFlags(knownMsvcWarningIds)...)
+	sort.Strings(flags)
+	return flags
+}
+
+// writeKnownMsvcWarningOptionsHeader writes the MSVC/clang-cl warning flag
+// table to path.
+func writeKnownMsvcWarningOptionsHeader(path string, flags []string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fmt.Fprint(f, `// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// This is auto generated by build/generate_known_warnings_list.go
+// DO NOT EDIT
+
+#ifndef DEVTOOLS_GOMA_LIB_KNOWN_MSVC_WARNING_OPTIONS_H_
+#define DEVTOOLS_GOMA_LIB_KNOWN_MSVC_WARNING_OPTIONS_H_
+
+namespace devtools_goma {
+const char* const kKnownMsvcWarningOptions[] {
+`)
+	for _, flag := range flags {
+		fmt.Fprintf(f, "  \"%s\",\n", flag)
+	}
+	fmt.Fprint(f, `};
+}  // namespace devtools_goma
+
+#endif  // DEVTOOLS_GOMA_LIB_KNOWN_MSVC_WARNING_OPTIONS_H_
+`)
+	return nil
+}
@@ -0,0 +1,110 @@
+// This file implements the non-C++ output formats: a Go source file that
+// the input processor (or other Go tooling in this module) can import
+// directly without a cgo dependency, and a JSON document that lets
+// external tooling regenerate its own bindings from the same canonical
+// data.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	goformat "go/format"
+	"os"
+	"sort"
+	"time"
+)
+
+// jsonSource mirrors sourceSnapshot with the field names used by the
+// "-format=json" output schema.
+type jsonSource struct {
+	Url    string `json:"url"`
+	Sha256 string `json:"sha256"`
+}
+
+// jsonOutput is the schema written by writeJsonOutput.
+type jsonOutput struct {
+	Options     []string            `json:"options"`
+	Groups      map[string][]string `json:"groups"`
+	Sources     []jsonSource        `json:"sources"`
+	GeneratedAt string              `json:"generated_at"`
+}
+
+// writeGoOutput writes a `package knownwarnings` Go source file exposing
+// the known warning options and the warning group graph as plain Go values.
+func writeGoOutput(path string, sortedWarnings []string, groups map[string][]string) error {
+	var buf bytes.Buffer
+	f := &buf
+
+	fmt.Fprint(f, `// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// This is auto generated by build/generate_known_warnings_list.go
+// DO NOT EDIT
+
+// Package knownwarnings holds the set of known "-W..." compiler warning
+// options and the parent/child structure of warning groups (e.g. "-Wall"
+// includes "-Wmost"), generated from the same upstream snapshot as the C++
+// headers in this repository.
+package knownwarnings
+
+// Options is the flat, deduplicated list of known "-W..." names, without
+// their "no-" prefix.
+var Options = []string{
+`)
+	for _, w := range sortedWarnings {
+		fmt.Fprintf(f, "\t%q,\n", w)
+	}
+	fmt.Fprint(f, `}
+
+// Groups maps a warning group name to the names of the groups and
+// warnings it directly includes, e.g. Groups["all"] contains "most".
+var Groups = map[string][]string{
+`)
+	var sortedGroups []string
+	for name := range groups {
+		sortedGroups = append(sortedGroups, name)
+	}
+	sort.Strings(sortedGroups)
+	for _, name := range sortedGroups {
+		fmt.Fprintf(f, "\t%q: {", name)
+		for i, child := range groups[name] {
+			if i > 0 {
+				fmt.Fprint(f, ", ")
+			}
+			fmt.Fprintf(f, "%q", child)
+		}
+		fmt.Fprint(f, "},\n")
+	}
+	fmt.Fprint(f, "}\n")
+
+	formatted, err := goformat.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, formatted, 0644)
+}
+
+// writeJsonOutput writes the stable JSON schema described in the
+// "-format=json" flag: known options, the warning group graph, the
+// upstream sources that produced them, and a generation timestamp.
+func writeJsonOutput(path string, sortedWarnings []string, groups map[string][]string) error {
+	var jsonSources []jsonSource
+	for _, s := range sources {
+		jsonSources = append(jsonSources, jsonSource{Url: s.url, Sha256: s.sha256})
+	}
+
+	out := jsonOutput{
+		Options:     sortedWarnings,
+		Groups:      groups,
+		Sources:     jsonSources,
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	return os.WriteFile(path, data, 0644)
+}